@@ -2,8 +2,10 @@ package db
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 
 	"github.com/etcd-io/bbolt"
@@ -18,7 +20,12 @@ func init() {
 }
 
 type BoltDB struct {
-	db *bbolt.DB
+	db     *bbolt.DB
+	bucket []byte
+	// syncMu guards toggling db.NoSync in BoltdbBatch.write. It is shared
+	// across every Bucket view of the same underlying *bbolt.DB, since
+	// NoSync is DB-wide, not per-bucket.
+	syncMu *sync.Mutex
 }
 
 func NewBoltDB(name, dir string) (DB, error) {
@@ -26,25 +33,54 @@ func NewBoltDB(name, dir string) (DB, error) {
 }
 
 func NewBoltDBWithOpts(name string, dir string, opts *bbolt.Options) (DB, error) {
+	return NewBoltDBWithBuckets(name, dir, opts, [][]byte{bucket})
+}
+
+// NewBoltDBWithBuckets opens (or creates) a BoltDB file with the given
+// buckets, returning a DB backed by the first bucket. Callers that want to
+// address the other buckets should go through Bucket.
+func NewBoltDBWithBuckets(name, dir string, opts *bbolt.Options, buckets [][]byte) (DB, error) {
+	if len(buckets) == 0 {
+		buckets = [][]byte{bucket}
+	}
 	dbPath := filepath.Join(dir, name+".db")
 	db, err := bbolt.Open(dbPath, os.ModePerm, opts)
 	if err != nil {
 		return nil, err
 	}
 	err = db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(bucket)
-		return err
+		for _, b := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &BoltDB{db: db}, nil
+	return &BoltDB{db: db, bucket: buckets[0], syncMu: &sync.Mutex{}}, nil
+}
+
+// Bucket returns a DB view scoped to the named bucket, creating it if it
+// does not already exist. The returned DB shares the underlying *bbolt.DB
+// with bdb, but its keys live only in the named bucket: unless name is
+// bdb's own bucket, writes through one are not visible through the other.
+func (bdb *BoltDB) Bucket(name []byte) DB {
+	err := bdb.db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(name)
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &BoltDB{db: bdb.db, bucket: name, syncMu: bdb.syncMu}
 }
 
 func (bdb *BoltDB) Get(key []byte) (value []byte) {
 	key = nonNilBytes(key)
 	err := bdb.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
+		b := tx.Bucket(bdb.bucket)
 		value = b.Get(key)
 		return nil
 	})
@@ -62,7 +98,7 @@ func (bdb *BoltDB) Set(key, value []byte) {
 	key = nonNilBytes(key)
 	value = nonNilBytes(value)
 	err := bdb.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
+		b := tx.Bucket(bdb.bucket)
 		return b.Put(key, value)
 	})
 	if err != nil {
@@ -77,7 +113,7 @@ func (bdb *BoltDB) SetSync(key, value []byte) {
 func (bdb *BoltDB) Delete(key []byte) {
 	key = nonNilBytes(key)
 	err := bdb.db.Update(func(tx *bbolt.Tx) error {
-		return tx.Bucket(bucket).Delete(key)
+		return tx.Bucket(bdb.bucket).Delete(key)
 	})
 	if err != nil {
 		panic(err)
@@ -88,58 +124,177 @@ func (bdb *BoltDB) DeleteSync(key []byte) {
 	bdb.Delete(key)
 }
 
+// AtomicDB is implemented by backends that can perform a compare-and-swap
+// without the caller needing to hold an external lock.
+type AtomicDB interface {
+	// AtomicSet sets key to new if and only if the current value of key
+	// equals expected, returning whether the swap happened.
+	AtomicSet(key, expected, new []byte) (bool, error)
+	// AtomicDelete deletes key if and only if its current value equals
+	// expected, returning whether the delete happened.
+	AtomicDelete(key, expected []byte) (bool, error)
+}
+
+func (bdb *BoltDB) AtomicSet(key, expected, new []byte) (bool, error) {
+	key = nonNilBytes(key)
+	new = nonNilBytes(new)
+	swapped := false
+	err := bdb.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bdb.bucket)
+		if !bytes.Equal(b.Get(key), expected) {
+			return nil
+		}
+		swapped = true
+		return b.Put(key, new)
+	})
+	if err != nil {
+		return false, err
+	}
+	return swapped, nil
+}
+
+func (bdb *BoltDB) AtomicDelete(key, expected []byte) (bool, error) {
+	key = nonNilBytes(key)
+	swapped := false
+	err := bdb.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bdb.bucket)
+		if !bytes.Equal(b.Get(key), expected) {
+			return nil
+		}
+		swapped = true
+		return b.Delete(key)
+	})
+	if err != nil {
+		return false, err
+	}
+	return swapped, nil
+}
+
 func (bdb *BoltDB) Close() {
 	bdb.db.Close()
 }
 
 func (bdb *BoltDB) Print() {
-	panic("boltdb.print not yet implemented")
+	stats := bdb.db.Stats()
+	fmt.Printf("%v\n", stats)
+	err := bdb.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bdb.bucket).ForEach(func(key, value []byte) error {
+			fmt.Printf("[%X]:\t[%X]\n", key, value)
+			return nil
+		})
+	})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (bdb *BoltDB) Stats() map[string]string {
-	panic("boltdb.stats not yet implemented")
+	dbStats := bdb.db.Stats()
+	stats := map[string]string{
+		"bolt.TxStats.PageCount": fmt.Sprintf("%v", dbStats.TxStats.PageCount),
+		"bolt.TxStats.WriteTime": fmt.Sprintf("%v", dbStats.TxStats.WriteTime),
+		"bolt.FreePageN":         fmt.Sprintf("%v", dbStats.FreePageN),
+		"bolt.PendingPageN":      fmt.Sprintf("%v", dbStats.PendingPageN),
+		"bolt.OpenTxN":           fmt.Sprintf("%v", dbStats.OpenTxN),
+	}
+	err := bdb.db.View(func(tx *bbolt.Tx) error {
+		bStats := tx.Bucket(bdb.bucket).Stats()
+		stats["bucket.KeyN"] = fmt.Sprintf("%v", bStats.KeyN)
+		stats["bucket.BranchPageN"] = fmt.Sprintf("%v", bStats.BranchPageN)
+		stats["bucket.LeafPageN"] = fmt.Sprintf("%v", bStats.LeafPageN)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return stats
+}
+
+// boltdbOp is a single buffered batch operation. Buffering ops in an
+// ordered slice, rather than a sync.Map, means later writes to the same
+// key deterministically overwrite earlier ones once the batch is written.
+type boltdbOp struct {
+	delete bool
+	key    []byte
+	value  []byte
 }
 
 type BoltdbBatch struct {
-	buffer *sync.Map
 	db     *BoltDB
+	ops    []boltdbOp
+	closed bool
 }
 
 func (bdb *BoltDB) NewBatch() Batch {
 	return &BoltdbBatch{
-		buffer: &sync.Map{},
-		db:     bdb,
+		db: bdb,
 	}
 }
 
 func (bdbb *BoltdbBatch) Set(key, value []byte) {
-	bdbb.buffer.Store(key, value)
+	bdbb.ops = append(bdbb.ops, boltdbOp{key: nonNilBytes(key), value: nonNilBytes(value)})
 }
 
 func (bdbb *BoltdbBatch) Delete(key []byte) {
-	bdbb.buffer.Delete(key)
+	bdbb.ops = append(bdbb.ops, boltdbOp{delete: true, key: nonNilBytes(key)})
 }
 
-func (bdbb *BoltdbBatch) Write() {
-	err := bdbb.db.db.Batch(func(tx *bbolt.Tx) error {
-		b := tx.Bucket(bucket)
-		bdbb.buffer.Range(func(key, value interface{}) bool {
-			b.Put(key.([]byte), value.([]byte))
-			return true
-		})
-		return nil
-	})
+// write applies the buffered ops in a single bbolt transaction, in order,
+// so that later ops on the same key win. sync forces the commit to fsync
+// even if the underlying *bbolt.DB has NoSync set.
+//
+// Toggling db.NoSync to force that fsync only touches a safe value while
+// syncMu is held: bbolt's own writer lock is released the instant
+// tx.Commit() returns, which is before this goroutine gets to restore
+// NoSync, so without syncMu a concurrent WriteSync on another goroutine
+// (or Bucket view of the same file) could read/write NoSync at the same
+// time we do.
+func (bdbb *BoltdbBatch) write(sync bool) {
+	if sync {
+		bdbb.db.syncMu.Lock()
+		defer bdbb.db.syncMu.Unlock()
+	}
+
+	tx, err := bdbb.db.db.Begin(true)
 	if err != nil {
 		panic(err)
 	}
+	b := tx.Bucket(bdbb.db.bucket)
+	for _, op := range bdbb.ops {
+		if op.delete {
+			err = b.Delete(op.key)
+		} else {
+			err = b.Put(op.key, op.value)
+		}
+		if err != nil {
+			tx.Rollback()
+			panic(err)
+		}
+	}
+	if sync {
+		prevNoSync := bdbb.db.db.NoSync
+		bdbb.db.db.NoSync = false
+		defer func() { bdbb.db.db.NoSync = prevNoSync }()
+	}
+	if err := tx.Commit(); err != nil {
+		panic(err)
+	}
+}
+
+func (bdbb *BoltdbBatch) Write() {
+	bdbb.write(false)
 }
 
 func (bdbb *BoltdbBatch) WriteSync() {
-	bdbb.Write()
+	bdbb.write(true)
 }
 
 func (bdbb *BoltdbBatch) Close() {
-	bdbb.buffer = nil
+	if bdbb.closed {
+		return
+	}
+	bdbb.ops = nil
+	bdbb.closed = true
 }
 
 func (bdb *BoltDB) Iterator(start, end []byte) Iterator {
@@ -147,8 +302,8 @@ func (bdb *BoltDB) Iterator(start, end []byte) Iterator {
 	if err != nil {
 		panic(err)
 	}
-	c := tx.Bucket(bucket).Cursor()
-	return newBoltdbIterator(c, start, end, false)
+	c := tx.Bucket(bdb.bucket).Cursor()
+	return newBoltdbIterator(tx, c, start, end, false)
 }
 
 func (bdb *BoltDB) ReverseIterator(start, end []byte) Iterator {
@@ -156,12 +311,17 @@ func (bdb *BoltDB) ReverseIterator(start, end []byte) Iterator {
 	if err != nil {
 		panic(err)
 	}
-	c := tx.Bucket(bucket).Cursor()
-	return newBoltdbIterator(c, start, end, true)
+	c := tx.Bucket(bdb.bucket).Cursor()
+	return newBoltdbIterator(tx, c, start, end, true)
 }
 
 type BoltdbIterator struct {
-	itr   *bbolt.Cursor
+	// tx is the read transaction backing itr. It is rolled back in Close
+	// so the iterator's snapshot pages can be reclaimed by bbolt's
+	// freelist instead of leaking for the lifetime of the DB.
+	tx  *bbolt.Tx
+	itr *bbolt.Cursor
+
 	start []byte
 	end   []byte
 
@@ -175,7 +335,7 @@ type BoltdbIterator struct {
 	isReverse bool
 }
 
-func newBoltdbIterator(itr *bbolt.Cursor, start, end []byte, isReverse bool) *BoltdbIterator {
+func newBoltdbIterator(tx *bbolt.Tx, itr *bbolt.Cursor, start, end []byte, isReverse bool) *BoltdbIterator {
 	var ck, cv []byte
 	if isReverse {
 		if end == nil {
@@ -191,7 +351,8 @@ func newBoltdbIterator(itr *bbolt.Cursor, start, end []byte, isReverse bool) *Bo
 		}
 	}
 
-	return &BoltdbIterator{
+	bdbi := &BoltdbIterator{
+		tx:        tx,
 		itr:       itr,
 		start:     start,
 		end:       end,
@@ -200,6 +361,8 @@ func newBoltdbIterator(itr *bbolt.Cursor, start, end []byte, isReverse bool) *Bo
 		isReverse: isReverse,
 		isInvalid: false,
 	}
+	runtime.SetFinalizer(bdbi, (*BoltdbIterator).Close)
+	return bdbi
 }
 
 func (bdbi *BoltdbIterator) Domain() ([]byte, []byte) {
@@ -246,8 +409,18 @@ func (bdbi *BoltdbIterator) Value() []byte {
 	return bdbi.cValue
 }
 
-// boltdb cursor has no close op.
-func (bdbi *BoltdbIterator) Close() {}
+// Close rolls back the read transaction backing the iterator. It is safe
+// to call more than once.
+func (bdbi *BoltdbIterator) Close() {
+	if bdbi.tx == nil {
+		return
+	}
+	// Tx may already be closed if the underlying *bbolt.DB was closed
+	// first; ignore the resulting error.
+	_ = bdbi.tx.Rollback()
+	bdbi.tx = nil
+	runtime.SetFinalizer(bdbi, nil)
+}
 
 func (bdbi *BoltdbIterator) assertIsValid() {
 	if !bdbi.Valid() {
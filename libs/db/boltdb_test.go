@@ -0,0 +1,194 @@
+package db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// newTestBoltDB opens a fresh BoltDB in a temp dir and returns it along
+// with a cleanup func that removes the dir.
+func newTestBoltDB(t *testing.T) (*BoltDB, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "boltdb_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ddb, err := NewBoltDB("test", dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	bdb := ddb.(*BoltDB)
+	return bdb, func() {
+		bdb.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// TestBoltDBIteratorClosesReadTx is a regression test for iterators that
+// never released the read transaction they opened: every Iterator call
+// used to leak a *bbolt.Tx, which blocks bbolt's freelist from reclaiming
+// pages and leads to unbounded file growth on long-running nodes.
+func TestBoltDBIteratorClosesReadTx(t *testing.T) {
+	bdb, cleanup := newTestBoltDB(t)
+	defer cleanup()
+
+	for i := 0; i < 100; i++ {
+		bdb.Set([]byte{byte(i)}, []byte{byte(i)})
+	}
+
+	const numIterators = 5000
+	for i := 0; i < numIterators; i++ {
+		itr := bdb.Iterator(nil, nil)
+		for ; itr.Valid(); itr.Next() {
+		}
+		itr.Close()
+
+		ritr := bdb.ReverseIterator(nil, nil)
+		for ; ritr.Valid(); ritr.Next() {
+		}
+		ritr.Close()
+	}
+
+	if openTxN := bdb.db.Stats().OpenTxN; openTxN != 0 {
+		t.Fatalf("expected 0 open read transactions after closing iterators, got %d", openTxN)
+	}
+
+	fi, err := os.Stat(bdb.db.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The loop above only reads, so it frees no pages and this bound only
+	// guards against gross file growth; a leaked read tx is caught by the
+	// OpenTxN check above.
+	const maxBytes = 1 << 20 // 1 MiB
+	if fi.Size() > maxBytes {
+		t.Fatalf("expected file size to stay bounded, got %d bytes", fi.Size())
+	}
+}
+
+// TestBoltDBAtomicSet exercises the CAS paths of AtomicSet: a successful
+// swap when expected matches, a no-op when it doesn't, and the "key
+// absent" create case where expected is nil.
+func TestBoltDBAtomicSet(t *testing.T) {
+	bdb, cleanup := newTestBoltDB(t)
+	defer cleanup()
+
+	key := []byte("foo")
+
+	// Key absent: expected nil should allow the create.
+	swapped, err := bdb.AtomicSet(key, nil, []byte("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !swapped {
+		t.Fatal("expected AtomicSet to succeed when key is absent and expected is nil")
+	}
+	if got := bdb.Get(key); string(got) != "bar" {
+		t.Fatalf("expected bar, got %q", got)
+	}
+
+	// Wrong expected value: swap must fail and leave the value untouched.
+	swapped, err = bdb.AtomicSet(key, []byte("not-bar"), []byte("baz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swapped {
+		t.Fatal("expected AtomicSet to fail when expected does not match")
+	}
+	if got := bdb.Get(key); string(got) != "bar" {
+		t.Fatalf("expected value to stay bar after failed swap, got %q", got)
+	}
+
+	// Matching expected value: swap must succeed.
+	swapped, err = bdb.AtomicSet(key, []byte("bar"), []byte("baz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !swapped {
+		t.Fatal("expected AtomicSet to succeed when expected matches")
+	}
+	if got := bdb.Get(key); string(got) != "baz" {
+		t.Fatalf("expected baz, got %q", got)
+	}
+}
+
+// TestBoltDBAtomicDelete exercises the CAS paths of AtomicDelete: a
+// successful delete when expected matches, and a no-op when it doesn't.
+func TestBoltDBAtomicDelete(t *testing.T) {
+	bdb, cleanup := newTestBoltDB(t)
+	defer cleanup()
+
+	key := []byte("foo")
+	bdb.Set(key, []byte("bar"))
+
+	// Wrong expected value: delete must fail and leave the key untouched.
+	swapped, err := bdb.AtomicDelete(key, []byte("not-bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swapped {
+		t.Fatal("expected AtomicDelete to fail when expected does not match")
+	}
+	if !bdb.Has(key) {
+		t.Fatal("expected key to still exist after failed AtomicDelete")
+	}
+
+	// Matching expected value: delete must succeed.
+	swapped, err = bdb.AtomicDelete(key, []byte("bar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !swapped {
+		t.Fatal("expected AtomicDelete to succeed when expected matches")
+	}
+	if bdb.Has(key) {
+		t.Fatal("expected key to be gone after successful AtomicDelete")
+	}
+}
+
+// TestBoltDBBatchDelete is a regression test for Delete in a batch being a
+// no-op: it used to just drop the pending Set from the buffer instead of
+// recording a deletion, silently leaving an existing on-disk key in place.
+func TestBoltDBBatchDelete(t *testing.T) {
+	bdb, cleanup := newTestBoltDB(t)
+	defer cleanup()
+
+	key := []byte("foo")
+	bdb.Set(key, []byte("bar"))
+
+	batch := bdb.NewBatch()
+	batch.Delete(key)
+	batch.Write()
+	batch.Close()
+
+	if bdb.Has(key) {
+		t.Fatal("expected key to be gone after Delete in a written batch")
+	}
+}
+
+// TestBoltDBBatchLaterOpWins confirms that Set(k, a); Delete(k); Set(k, b)
+// on the same batch leaves k == b: the ordered boltdbOp slice must apply
+// ops in order so later writes to the same key deterministically overwrite
+// earlier ones, rather than the old sync.Map buffer's unspecified order.
+func TestBoltDBBatchLaterOpWins(t *testing.T) {
+	bdb, cleanup := newTestBoltDB(t)
+	defer cleanup()
+
+	key := []byte("foo")
+
+	batch := bdb.NewBatch()
+	batch.Set(key, []byte("a"))
+	batch.Delete(key)
+	batch.Set(key, []byte("b"))
+	batch.Write()
+	batch.Close()
+
+	if got := bdb.Get(key); string(got) != "b" {
+		t.Fatalf("expected b, got %q", got)
+	}
+}
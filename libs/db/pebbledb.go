@@ -0,0 +1,253 @@
+package db
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// PebbleDBBackend registers PebbleDB alongside the other backend types
+// (BoltDBBackend, etc.) in the package's backend-type enum.
+const PebbleDBBackend DBBackendType = "pebbledb"
+
+func init() {
+	registerDBCreator(PebbleDBBackend, func(name, dir string) (DB, error) {
+		return NewPebbleDB(name, dir)
+	}, false)
+}
+
+// PebbleDB is a DB backend implemented on top of a Pebble LSM-tree store.
+// Pebble favors write-heavy, append-mostly workloads over BoltDB's B+tree,
+// which fits the block/state persistence access pattern better.
+type PebbleDB struct {
+	db *pebble.DB
+}
+
+func NewPebbleDB(name, dir string) (DB, error) {
+	return NewPebbleDBWithOpts(name, dir, &pebble.Options{})
+}
+
+func NewPebbleDBWithOpts(name string, dir string, opts *pebble.Options) (DB, error) {
+	dbPath := filepath.Join(dir, name+".db")
+	db, err := pebble.Open(dbPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PebbleDB{db: db}, nil
+}
+
+func (pdb *PebbleDB) Get(key []byte) []byte {
+	key = nonNilBytes(key)
+	value, closer, err := pdb.db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil
+	} else if err != nil {
+		panic(err)
+	}
+	defer closer.Close()
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	return valueCopy
+}
+
+func (pdb *PebbleDB) Has(key []byte) bool {
+	return pdb.Get(key) != nil
+}
+
+func (pdb *PebbleDB) Set(key, value []byte) {
+	key = nonNilBytes(key)
+	value = nonNilBytes(value)
+	if err := pdb.db.Set(key, value, nil); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) SetSync(key, value []byte) {
+	key = nonNilBytes(key)
+	value = nonNilBytes(value)
+	if err := pdb.db.Set(key, value, pebble.Sync); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) Delete(key []byte) {
+	key = nonNilBytes(key)
+	if err := pdb.db.Delete(key, nil); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) DeleteSync(key []byte) {
+	key = nonNilBytes(key)
+	if err := pdb.db.Delete(key, pebble.Sync); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) Close() {
+	if err := pdb.db.Close(); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) Print() {
+	metrics := pdb.db.Metrics()
+	fmt.Printf("%s\n", metrics.String())
+}
+
+func (pdb *PebbleDB) Stats() map[string]string {
+	metrics := pdb.db.Metrics()
+	return map[string]string{
+		"pebble.compactions":  fmt.Sprintf("%v", metrics.Compact.Count),
+		"pebble.flushes":      fmt.Sprintf("%v", metrics.Flush.Count),
+		"pebble.mem-tables":   fmt.Sprintf("%v", metrics.MemTable.Count),
+		"pebble.num-sstables": fmt.Sprintf("%v", metrics.NumSSTables()),
+		"pebble.disk-space":   fmt.Sprintf("%v", metrics.DiskSpaceUsage()),
+		"pebble.read-amp":     fmt.Sprintf("%v", metrics.ReadAmp()),
+	}
+}
+
+type PebbleDBBatch struct {
+	db    *PebbleDB
+	batch *pebble.Batch
+}
+
+func (pdb *PebbleDB) NewBatch() Batch {
+	return &PebbleDBBatch{
+		db:    pdb,
+		batch: pdb.db.NewBatch(),
+	}
+}
+
+func (pdbb *PebbleDBBatch) Set(key, value []byte) {
+	if err := pdbb.batch.Set(nonNilBytes(key), nonNilBytes(value), nil); err != nil {
+		panic(err)
+	}
+}
+
+func (pdbb *PebbleDBBatch) Delete(key []byte) {
+	if err := pdbb.batch.Delete(nonNilBytes(key), nil); err != nil {
+		panic(err)
+	}
+}
+
+func (pdbb *PebbleDBBatch) Write() {
+	if err := pdbb.db.db.Apply(pdbb.batch, nil); err != nil {
+		panic(err)
+	}
+}
+
+func (pdbb *PebbleDBBatch) WriteSync() {
+	if err := pdbb.db.db.Apply(pdbb.batch, pebble.Sync); err != nil {
+		panic(err)
+	}
+}
+
+func (pdbb *PebbleDBBatch) Close() {
+	if err := pdbb.batch.Close(); err != nil {
+		panic(err)
+	}
+}
+
+func (pdb *PebbleDB) Iterator(start, end []byte) Iterator {
+	snapshot := pdb.db.NewSnapshot()
+	itr := snapshot.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: end})
+	return newPebbleDBIterator(snapshot, itr, start, end, false)
+}
+
+func (pdb *PebbleDB) ReverseIterator(start, end []byte) Iterator {
+	snapshot := pdb.db.NewSnapshot()
+	itr := snapshot.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: end})
+	return newPebbleDBIterator(snapshot, itr, start, end, true)
+}
+
+type PebbleDBIterator struct {
+	snapshot  *pebble.Snapshot
+	source    *pebble.Iterator
+	start     []byte
+	end       []byte
+	isReverse bool
+	isInvalid bool
+}
+
+func newPebbleDBIterator(snapshot *pebble.Snapshot, source *pebble.Iterator, start, end []byte, isReverse bool) *PebbleDBIterator {
+	var valid bool
+	if isReverse {
+		if end == nil {
+			valid = source.Last()
+		} else {
+			valid = source.SeekLT(end)
+		}
+	} else {
+		if start == nil {
+			valid = source.First()
+		} else {
+			valid = source.SeekGE(start)
+		}
+	}
+
+	return &PebbleDBIterator{
+		snapshot:  snapshot,
+		source:    source,
+		start:     start,
+		end:       end,
+		isReverse: isReverse,
+		isInvalid: !valid,
+	}
+}
+
+func (itr *PebbleDBIterator) Domain() ([]byte, []byte) {
+	return itr.start, itr.end
+}
+
+func (itr *PebbleDBIterator) Valid() bool {
+	if itr.isInvalid {
+		return false
+	}
+	if !itr.source.Valid() {
+		itr.isInvalid = true
+		return false
+	}
+	return true
+}
+
+func (itr *PebbleDBIterator) Next() {
+	itr.assertIsValid()
+	var valid bool
+	if itr.isReverse {
+		valid = itr.source.Prev()
+	} else {
+		valid = itr.source.Next()
+	}
+	if !valid {
+		itr.isInvalid = true
+	}
+}
+
+func (itr *PebbleDBIterator) Key() []byte {
+	itr.assertIsValid()
+	key := itr.source.Key()
+	keyCopy := make([]byte, len(key))
+	copy(keyCopy, key)
+	return keyCopy
+}
+
+func (itr *PebbleDBIterator) Value() []byte {
+	itr.assertIsValid()
+	value := itr.source.Value()
+	valueCopy := make([]byte, len(value))
+	copy(valueCopy, value)
+	return valueCopy
+}
+
+func (itr *PebbleDBIterator) Close() {
+	itr.source.Close()
+	itr.snapshot.Close()
+}
+
+func (itr *PebbleDBIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("PebbleDB-iterator is invalid")
+	}
+}
@@ -0,0 +1,237 @@
+package remotedb
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	db "github.com/tendermint/tendermint/libs/db"
+	protodb "github.com/tendermint/tendermint/libs/db/remotedb/proto"
+)
+
+// RemoteDB is a db.DB backed by a DB living behind a gRPC connection,
+// letting callers transparently swap a local BoltDB for one running in a
+// sidecar process or a shared fixture.
+type RemoteDB struct {
+	dc   protodb.DBClient
+	conn *grpc.ClientConn
+}
+
+// NewRemoteDB dials addr and returns a RemoteDB talking to the DB server
+// listening there. Pass an empty certFile to connect without TLS.
+func NewRemoteDB(addr, certFile string) (*RemoteDB, error) {
+	var opt grpc.DialOption
+	if certFile == "" {
+		opt = grpc.WithInsecure()
+	} else {
+		creds, err := credentials.NewClientTLSFromFile(certFile, "")
+		if err != nil {
+			return nil, err
+		}
+		opt = grpc.WithTransportCredentials(creds)
+	}
+	conn, err := grpc.Dial(addr, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteDB{dc: protodb.NewDBClient(conn), conn: conn}, nil
+}
+
+// NewRemoteDBWithTLSConfig is like NewRemoteDB but takes an explicit TLS
+// config, for callers that need custom root CAs or client certificates.
+func NewRemoteDBWithTLSConfig(addr string, cfg *tls.Config) (*RemoteDB, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteDB{dc: protodb.NewDBClient(conn), conn: conn}, nil
+}
+
+var _ db.DB = (*RemoteDB)(nil)
+
+func (r *RemoteDB) Get(key []byte) []byte {
+	out, err := r.dc.Get(context.Background(), &protodb.Entity{Key: key})
+	if err != nil {
+		panic(err)
+	}
+	if !out.Exists {
+		return nil
+	}
+	return out.Value
+}
+
+func (r *RemoteDB) Has(key []byte) bool {
+	out, err := r.dc.Has(context.Background(), &protodb.Entity{Key: key})
+	if err != nil {
+		panic(err)
+	}
+	return out.Exists
+}
+
+func (r *RemoteDB) Set(key, value []byte) {
+	if _, err := r.dc.Set(context.Background(), &protodb.Entity{Key: key, Value: value}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) SetSync(key, value []byte) {
+	if _, err := r.dc.SetSync(context.Background(), &protodb.Entity{Key: key, Value: value}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) Delete(key []byte) {
+	if _, err := r.dc.Delete(context.Background(), &protodb.Entity{Key: key}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) DeleteSync(key []byte) {
+	if _, err := r.dc.DeleteSync(context.Background(), &protodb.Entity{Key: key}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) Close() {
+	if err := r.conn.Close(); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) Print() {
+	if _, err := r.dc.Print(context.Background(), &protodb.Nothing{}); err != nil {
+		panic(err)
+	}
+}
+
+func (r *RemoteDB) Stats() map[string]string {
+	out, err := r.dc.Stats(context.Background(), &protodb.Nothing{})
+	if err != nil {
+		panic(err)
+	}
+	return out.Data
+}
+
+func (r *RemoteDB) Iterator(start, end []byte) db.Iterator {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := r.dc.Iterator(ctx, &protodb.Domain{Start: start, End: end})
+	if err != nil {
+		cancel()
+		panic(err)
+	}
+	return newRemoteDBIterator(start, end, stream, cancel)
+}
+
+func (r *RemoteDB) ReverseIterator(start, end []byte) db.Iterator {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := r.dc.ReverseIterator(ctx, &protodb.Domain{Start: start, End: end})
+	if err != nil {
+		cancel()
+		panic(err)
+	}
+	return newRemoteDBIterator(start, end, stream, cancel)
+}
+
+// remoteDBIterator adapts the server-streamed Entity RPC into a db.Iterator
+// by eagerly pulling one entry ahead, so Valid reflects whether Recv has
+// already observed io.EOF. cancel tears down the RPC's context on Close,
+// so breaking out of an iteration early (the whole point of Close existing
+// on the Iterator interface) unblocks the server's streamIterator instead
+// of leaving it pinning a read transaction until the connection closes.
+type remoteDBIterator struct {
+	start, end []byte
+	stream     interface{ Recv() (*protodb.Entity, error) }
+	cancel     context.CancelFunc
+
+	cur    *protodb.Entity
+	valid  bool
+	closed bool
+}
+
+func newRemoteDBIterator(start, end []byte, stream interface{ Recv() (*protodb.Entity, error) }, cancel context.CancelFunc) *remoteDBIterator {
+	itr := &remoteDBIterator{start: start, end: end, stream: stream, cancel: cancel}
+	itr.advance()
+	return itr
+}
+
+func (itr *remoteDBIterator) advance() {
+	entity, err := itr.stream.Recv()
+	if err == io.EOF {
+		itr.cur, itr.valid = nil, false
+		return
+	}
+	if err != nil {
+		panic(err)
+	}
+	itr.cur, itr.valid = entity, true
+}
+
+func (itr *remoteDBIterator) Domain() ([]byte, []byte) { return itr.start, itr.end }
+func (itr *remoteDBIterator) Valid() bool              { return itr.valid }
+
+func (itr *remoteDBIterator) Next() {
+	itr.assertIsValid()
+	itr.advance()
+}
+
+func (itr *remoteDBIterator) Key() []byte {
+	itr.assertIsValid()
+	return itr.cur.Key
+}
+
+func (itr *remoteDBIterator) Value() []byte {
+	itr.assertIsValid()
+	return itr.cur.Value
+}
+
+func (itr *remoteDBIterator) Close() {
+	if itr.closed {
+		return
+	}
+	itr.cancel()
+	itr.closed = true
+}
+
+func (itr *remoteDBIterator) assertIsValid() {
+	if !itr.valid {
+		panic("remoteDB-iterator is invalid")
+	}
+}
+
+// NewBatch returns a Batch that buffers ops locally and ships them to the
+// server as a single BatchWrite/BatchWriteSync RPC on Write/WriteSync.
+func (r *RemoteDB) NewBatch() db.Batch {
+	return &remoteDBBatch{db: r}
+}
+
+type remoteDBBatch struct {
+	db  *RemoteDB
+	ops []*protodb.BatchEntry
+}
+
+func (b *remoteDBBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, &protodb.BatchEntry{Key: key, Value: value})
+}
+
+func (b *remoteDBBatch) Delete(key []byte) {
+	b.ops = append(b.ops, &protodb.BatchEntry{Delete: true, Key: key})
+}
+
+func (b *remoteDBBatch) Write() {
+	if _, err := b.db.dc.BatchWrite(context.Background(), &protodb.Batch{Ops: b.ops}); err != nil {
+		panic(err)
+	}
+}
+
+func (b *remoteDBBatch) WriteSync() {
+	if _, err := b.db.dc.BatchWriteSync(context.Background(), &protodb.Batch{Ops: b.ops}); err != nil {
+		panic(err)
+	}
+}
+
+func (b *remoteDBBatch) Close() {
+	b.ops = nil
+}
@@ -0,0 +1,175 @@
+package remotedb
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	db "github.com/tendermint/tendermint/libs/db"
+)
+
+// memDB is a minimal in-memory db.DB used only to give
+// TestRemoteDBConformance a second, non-disk-backed backend to run
+// against, alongside BoltDB.
+type memDB struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemDB(dir string) (db.DB, error) {
+	return &memDB{data: map[string][]byte{}}, nil
+}
+
+func (m *memDB) Get(key []byte) []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.data[string(key)]
+}
+
+func (m *memDB) Has(key []byte) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.data[string(key)]
+	return ok
+}
+
+func (m *memDB) Set(key, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[string(key)] = value
+}
+
+func (m *memDB) SetSync(key, value []byte) { m.Set(key, value) }
+
+func (m *memDB) Delete(key []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, string(key))
+}
+
+func (m *memDB) DeleteSync(key []byte) { m.Delete(key) }
+
+func (m *memDB) Close() {}
+
+func (m *memDB) Print() {}
+
+func (m *memDB) Stats() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return map[string]string{"memdb.keys": fmt.Sprintf("%d", len(m.data))}
+}
+
+type memDBBatch struct {
+	db  *memDB
+	ops []boltdbLikeOp
+}
+
+// boltdbLikeOp mirrors the ordered-op buffering db.BoltdbBatch uses, so
+// later ops on the same key win once the batch is written.
+type boltdbLikeOp struct {
+	delete bool
+	key    []byte
+	value  []byte
+}
+
+func (m *memDB) NewBatch() db.Batch {
+	return &memDBBatch{db: m}
+}
+
+func (b *memDBBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, boltdbLikeOp{key: key, value: value})
+}
+
+func (b *memDBBatch) Delete(key []byte) {
+	b.ops = append(b.ops, boltdbLikeOp{delete: true, key: key})
+}
+
+func (b *memDBBatch) write() {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+	for _, op := range b.ops {
+		if op.delete {
+			delete(b.db.data, string(op.key))
+		} else {
+			b.db.data[string(op.key)] = op.value
+		}
+	}
+}
+
+func (b *memDBBatch) Write()     { b.write() }
+func (b *memDBBatch) WriteSync() { b.write() }
+func (b *memDBBatch) Close()     { b.ops = nil }
+
+func (m *memDB) Iterator(start, end []byte) db.Iterator {
+	return m.makeIterator(start, end, false)
+}
+
+func (m *memDB) ReverseIterator(start, end []byte) db.Iterator {
+	return m.makeIterator(start, end, true)
+}
+
+func (m *memDB) makeIterator(start, end []byte, isReverse bool) db.Iterator {
+	m.mu.Lock()
+	keys := make([]string, 0, len(m.data))
+	for k := range m.data {
+		key := []byte(k)
+		if start != nil && bytes.Compare(key, start) < 0 {
+			continue
+		}
+		if end != nil && bytes.Compare(key, end) >= 0 {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	m.mu.Unlock()
+
+	sort.Strings(keys)
+	if isReverse {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	values := make([][]byte, len(keys))
+	m.mu.Lock()
+	for i, k := range keys {
+		values[i] = m.data[k]
+	}
+	m.mu.Unlock()
+
+	return &memDBIterator{start: start, end: end, keys: keys, values: values}
+}
+
+type memDBIterator struct {
+	start, end []byte
+	keys       []string
+	values     [][]byte
+	idx        int
+}
+
+func (itr *memDBIterator) Domain() ([]byte, []byte) { return itr.start, itr.end }
+func (itr *memDBIterator) Valid() bool              { return itr.idx < len(itr.keys) }
+
+func (itr *memDBIterator) Next() {
+	itr.assertIsValid()
+	itr.idx++
+}
+
+func (itr *memDBIterator) Key() []byte {
+	itr.assertIsValid()
+	return []byte(itr.keys[itr.idx])
+}
+
+func (itr *memDBIterator) Value() []byte {
+	itr.assertIsValid()
+	return itr.values[itr.idx]
+}
+
+func (itr *memDBIterator) Close() {}
+
+func (itr *memDBIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("memDB-iterator is invalid")
+	}
+}
@@ -0,0 +1,143 @@
+package remotedb
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	db "github.com/tendermint/tendermint/libs/db"
+	protodb "github.com/tendermint/tendermint/libs/db/remotedb/proto"
+)
+
+// server implements protodb.DBServer on top of a local db.DB, so that
+// a DB living in this process can be driven from elsewhere over gRPC.
+type server struct {
+	db db.DB
+}
+
+// NewServer wraps ddb behind a protodb.DBServer registered on a new,
+// insecure *grpc.Server. Call Serve on the result with a listener to start
+// serving it; ListenAndServeTLS does the same over TLS.
+func NewServer(ddb db.DB) *grpc.Server {
+	gs := grpc.NewServer()
+	protodb.RegisterDBServer(gs, &server{db: ddb})
+	return gs
+}
+
+// ListenAndServeTLS serves ddb over a TLS listener on addr, using the
+// certificate/key pair at certFile/keyFile.
+func ListenAndServeTLS(ddb db.DB, addr, certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("remotedb: loading TLS keypair: %w", err)
+	}
+	creds := credentials.NewServerTLSFromCert(&cert)
+	gs := grpc.NewServer(grpc.Creds(creds))
+	protodb.RegisterDBServer(gs, &server{db: ddb})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return gs.Serve(ln)
+}
+
+func (s *server) Init(ctx context.Context, in *protodb.Init) (*protodb.Nothing, error) {
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) Get(ctx context.Context, in *protodb.Entity) (*protodb.Entity, error) {
+	value := s.db.Get(in.Key)
+	return &protodb.Entity{Key: in.Key, Value: value, Exists: value != nil}, nil
+}
+
+func (s *server) Has(ctx context.Context, in *protodb.Entity) (*protodb.Entity, error) {
+	return &protodb.Entity{Key: in.Key, Exists: s.db.Has(in.Key)}, nil
+}
+
+func (s *server) Set(ctx context.Context, in *protodb.Entity) (*protodb.Nothing, error) {
+	s.db.Set(in.Key, in.Value)
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) SetSync(ctx context.Context, in *protodb.Entity) (*protodb.Nothing, error) {
+	s.db.SetSync(in.Key, in.Value)
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) Delete(ctx context.Context, in *protodb.Entity) (*protodb.Nothing, error) {
+	s.db.Delete(in.Key)
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) DeleteSync(ctx context.Context, in *protodb.Entity) (*protodb.Nothing, error) {
+	s.db.DeleteSync(in.Key)
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) Iterator(d *protodb.Domain, stream protodb.DB_IteratorServer) error {
+	return s.streamIterator(s.db.Iterator(d.Start, d.End), stream)
+}
+
+func (s *server) ReverseIterator(d *protodb.Domain, stream protodb.DB_ReverseIteratorServer) error {
+	return s.streamIterator(s.db.ReverseIterator(d.Start, d.End), stream)
+}
+
+func (s *server) streamIterator(itr db.Iterator, stream grpc.ServerStream) error {
+	defer itr.Close()
+	ctx := stream.Context()
+	for ; itr.Valid(); itr.Next() {
+		select {
+		case <-ctx.Done():
+			// The client cancelled (e.g. it broke out of iteration early
+			// via Close) — stop driving itr so its read transaction is
+			// released instead of being pinned until exhaustion.
+			return ctx.Err()
+		default:
+		}
+		entity := &protodb.Entity{Exists: true, Key: itr.Key(), Value: itr.Value()}
+		if err := stream.SendMsg(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) BatchWrite(ctx context.Context, in *protodb.Batch) (*protodb.Nothing, error) {
+	return s.batchWrite(in, false)
+}
+
+func (s *server) BatchWriteSync(ctx context.Context, in *protodb.Batch) (*protodb.Nothing, error) {
+	return s.batchWrite(in, true)
+}
+
+func (s *server) batchWrite(in *protodb.Batch, sync bool) (*protodb.Nothing, error) {
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	for _, op := range in.Ops {
+		if op.Delete {
+			batch.Delete(op.Key)
+		} else {
+			batch.Set(op.Key, op.Value)
+		}
+	}
+	if sync {
+		batch.WriteSync()
+	} else {
+		batch.Write()
+	}
+	return &protodb.Nothing{}, nil
+}
+
+func (s *server) Stats(ctx context.Context, in *protodb.Nothing) (*protodb.Stats, error) {
+	return &protodb.Stats{Data: s.db.Stats()}, nil
+}
+
+func (s *server) Print(ctx context.Context, in *protodb.Nothing) (*protodb.Nothing, error) {
+	s.db.Print()
+	return &protodb.Nothing{}, nil
+}